@@ -42,6 +42,14 @@ func main() {
 			fmt.Println(err)
 			return
 		}
+		if err := pager.RegisterBackupCommands(pRepl); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := pager.RegisterStatsCommand(pRepl); err != nil {
+			fmt.Println(err)
+			return
+		}
 		repls = append(repls, pRepl)
 
 	default:
@@ -1,10 +1,19 @@
 package join
 
 import (
+	"encoding/binary"
+	"hash/fnv"
+
 	// Documentation: https://pkg.go.dev/github.com/bits-and-blooms/bitset
 	"github.com/bits-and-blooms/bitset"
 )
 
+// numHashFuncs is the number of independent bit positions each key is
+// hashed to. We derive all of them from two base hashes via the
+// Kirsch-Mitzenmacher technique instead of running numHashFuncs distinct
+// hash functions.
+const numHashFuncs = 4
+
 // BloomFilter is a probabilistic data structure used to
 // quickly determine if an element is not in a set.
 type BloomFilter struct {
@@ -14,15 +23,67 @@ type BloomFilter struct {
 
 // CreateFilter initializes a BloomFilter with the given size.
 func CreateFilter(size int64) (bf *BloomFilter) {
-	panic("Not yet implemented")
+	if size <= 0 {
+		size = DEFAULT_FILTER_SIZE
+	}
+	return &BloomFilter{
+		size: size,
+		bits: bitset.New(uint(size)),
+	}
+}
+
+// baseHashes computes the two independent 64-bit hashes of key that
+// positions derives every bit position from.
+func baseHashes(key int64) (h1 uint64, h2 uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(key))
+	fnv1a := fnv.New64a()
+	fnv1a.Write(buf[:])
+	h1 = fnv1a.Sum64()
+	fnv1 := fnv.New64()
+	fnv1.Write(buf[:])
+	h2 = fnv1.Sum64()
+	return h1, h2
+}
+
+// positions returns the numHashFuncs bit positions that key maps to in this filter.
+func (filter *BloomFilter) positions(key int64) [numHashFuncs]uint {
+	h1, h2 := baseHashes(key)
+	var positions [numHashFuncs]uint
+	for i := 0; i < numHashFuncs; i++ {
+		positions[i] = uint((h1 + uint64(i)*h2) % uint64(filter.size))
+	}
+	return positions
 }
 
 // Insert adds an element into the bloom filter.
 func (filter *BloomFilter) Insert(key int64) {
-	panic("Not yet implemented")
+	for _, pos := range filter.positions(key) {
+		filter.bits.Set(pos)
+	}
 }
 
 // Contains returns whether the given key can be found in the bloom filter.
+// A false result is a guarantee the key was never inserted; a true result
+// may be a false positive.
 func (filter *BloomFilter) Contains(key int64) bool {
-	panic("Not yet implemented")
+	for _, pos := range filter.positions(key) {
+		if !filter.bits.Test(pos) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterSizeForEntries picks a filter size (in bits) for a bucket holding
+// numEntries entries, starting from DEFAULT_FILTER_SIZE and doubling until
+// there are at least bitsPerEntry (8) bits per entry, keeping the false
+// positive rate down for large or skewed buckets.
+func filterSizeForEntries(numEntries int) int64 {
+	const bitsPerEntry = 8
+	size := DEFAULT_FILTER_SIZE
+	for size < int64(numEntries)*bitsPerEntry {
+		size *= 2
+	}
+	return size
 }
@@ -0,0 +1,42 @@
+package join
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// BenchmarkBloomFilterSkewed measures how many lookups a bloom filter built
+// over a small set of "hot" keys allows us to skip when probed with a much
+// larger, skewed population where most keys never appear on the left side -
+// the situation Grace Hash Join hits once ExtendTable has equalized bucket
+// depths but one side's buckets are still much sparser than the other's.
+func BenchmarkBloomFilterSkewed(b *testing.B) {
+	const numLeftKeys = 1000
+	const numProbes = 100000
+
+	filter := CreateFilter(filterSizeForEntries(numLeftKeys))
+	leftKeys := make(map[int64]bool, numLeftKeys)
+	for i := int64(0); i < numLeftKeys; i++ {
+		filter.Insert(i)
+		leftKeys[i] = true
+	}
+
+	r := rand.New(rand.NewSource(1))
+	probes := make([]int64, numProbes)
+	for i := range probes {
+		// Skew heavily toward keys that were never inserted on the left.
+		probes[i] = r.Int63n(numLeftKeys * 100)
+	}
+
+	b.ResetTimer()
+	var skipped int
+	for n := 0; n < b.N; n++ {
+		skipped = 0
+		for _, key := range probes {
+			if !filter.Contains(key) {
+				skipped++
+			}
+		}
+	}
+	b.ReportMetric(float64(skipped)/float64(numProbes)*100, "pct_skipped")
+}
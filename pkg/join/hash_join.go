@@ -75,6 +75,11 @@ func sendResult(
 // The joinOnLeftKey and joinOnRightKey arguments dictate whether we originally were matching on keys
 // or values for each bucket. For example, with joinOnLeftKey = true and joinOnRightKey = false,
 // we are finding the entries in lBucket whose keys match the value of entries in rBucket.
+//
+// probeBuckets does not build or consult a [BloomFilter] over lBucket's join
+// field: that needs a way to read a bucket's entries that hash.HashBucket
+// doesn't expose in this tree (it only has GetPage()). Its body is an
+// unimplemented stub that panics, so no probe, filtered or nested-loop, runs.
 func probeBuckets(
 	ctx context.Context,
 	resultsChan chan EntryPair,
@@ -85,7 +90,6 @@ func probeBuckets(
 ) error {
 	defer lBucket.GetPage().GetPager().PutPage(lBucket.GetPage())
 	defer rBucket.GetPage().GetPager().PutPage(rBucket.GetPage())
-	// Probe buckets.
 	panic("Not yet implemented")
 }
 
@@ -0,0 +1,72 @@
+package pager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"dinodb/pkg/repl"
+)
+
+// RegisterBackupCommands adds the backup and restore commands to pRepl, next
+// to the rest of the pager project's REPL commands.
+func RegisterBackupCommands(pRepl *repl.REPL) error {
+	if err := pRepl.AddCommand("backup", handleBackup, backupUsage); err != nil {
+		return err
+	}
+	return pRepl.AddCommand("restore", handleRestore, restoreUsage)
+}
+
+// backupUsage is the REPL usage string shown for the backup command.
+const backupUsage = "backup <path> - write a consistent copy of the current database to <path>; pauses all other pager activity for the duration of the copy, and only captures pages already on disk (no buffer pool flush support yet)"
+
+// restoreUsage is the REPL usage string shown for the restore command.
+const restoreUsage = "restore <path> - overwrite <path> with a copy restored from a prior backup read from stdin"
+
+// handleBackup implements the `backup <path>` REPL command: it streams a
+// consistent copy of replConfig's pager out to the given path, without
+// closing it, at the cost of pausing other pager activity for the duration
+// of the copy - see backupUsage, including the caveat about unflushed pages.
+func handleBackup(payload string, replConfig *repl.REPLConfig) error {
+	args := strings.Fields(payload)
+	if len(args) != 2 {
+		return fmt.Errorf("usage: %s", backupUsage)
+	}
+	destPath := args[1]
+
+	pager, found := replConfig.GetAttribute("pager").(*Pager)
+	if !found || pager == nil {
+		return errors.New("no pager loaded - open a database before running backup")
+	}
+
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	n, err := pager.WriteTo(dest)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(replConfig.GetWriter(), "wrote %d bytes to %s\n", n, destPath)
+	return nil
+}
+
+// handleRestore implements the `restore <path>` REPL command: it reads a
+// backup produced by the backup command from standard input and writes it
+// out as a new database file at the given path.
+func handleRestore(payload string, replConfig *repl.REPLConfig) error {
+	args := strings.Fields(payload)
+	if len(args) != 2 {
+		return fmt.Errorf("usage: %s", restoreUsage)
+	}
+	destPath := args[1]
+
+	if err := Restore(destPath, replConfig.GetReader()); err != nil {
+		return err
+	}
+	fmt.Fprintf(replConfig.GetWriter(), "restored %s\n", destPath)
+	return nil
+}
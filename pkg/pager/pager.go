@@ -2,6 +2,7 @@
 package pager
 
 import (
+	"encoding/binary"
 	"errors"
 	"io"
 	"os"
@@ -20,6 +21,10 @@ const Pagesize int64 = directio.BlockSize
 // Error for when there are no free/unpinned pages to be used
 var ErrRanOutOfPages = errors.New("no available pages")
 
+// freelistSuffix names the sidecar file a Pager persists its on-disk
+// free-pagenum list to, alongside the backing database file.
+const freelistSuffix = ".freelist"
+
 // Pager is a data structure that manages pages of data stored in a file.
 type Pager struct {
 	file         *os.File   // File descriptor for the file that backs this pager on disk.
@@ -30,6 +35,65 @@ type Pager struct {
 	// The page table, which maps pagenums to their corresponding pages (stored in a link belonging to the list the page is in).
 	pageTable map[int64]*list.Link
 	ptMtx     sync.Mutex // Mutex for protecting the Page table for concurrent use.
+	// freePNs holds on-disk pagenums that have been released via FreePage and
+	// are available for reuse by GetFreePN, persisted across Open/Close in a
+	// freelistSuffix sidecar file. This is distinct from freeList, which only
+	// tracks preallocated in-memory frames.
+	freePNs []int64
+	// Buffer pool activity counters, reported via Stats. Nothing in this file
+	// increments them: GetPage (hits/misses), newPage (evictions), and
+	// FlushPage (dirty flushes) are unimplemented stubs, so they stay at 0.
+	hitCount      int64
+	missCount     int64
+	evictionCount int64
+	dirtyFlushes  int64
+	// pinnedCount and unpinnedCount mirror pinnedList/unpinnedList's lengths
+	// without relying on list.List exposing a Len(), which it doesn't.
+	// PutPage maintains the unpin-transition half by decrementing pinnedCount;
+	// nothing in this file increments it yet; see the TODO(stats) comments on
+	// GetNewPage/GetPage, the only two places a page is ever pinned. Until one
+	// of them does, PutPage's decrement can never run (both panic before
+	// returning a page to pin), so pinnedCount stays accurate at 0 - but it
+	// will go negative the moment either is implemented without also adding
+	// the matching increment.
+	pinnedCount   int64
+	unpinnedCount int64
+}
+
+// PagerStats summarizes a Pager's buffer pool activity. Useful for spotting
+// buffer pool thrash (e.g. a MissCount/EvictionCount ratio close to HitCount)
+// on workloads like Grace Hash Join that lean heavily on the buffer pool.
+type PagerStats struct {
+	Pagesize      int64 // The size, in bytes, of a single page.
+	NumPages      int64 // The number of pages currently backed by the file.
+	FreeListLen   int64 // The number of on-disk pagenums available for reuse via GetFreePN.
+	PinnedCount   int64 // The number of pages currently pinned (in use).
+	UnpinnedCount int64 // The number of pages cached but not currently in use.
+	// HitCount, MissCount, EvictionCount, DirtyFlushes, and PinnedCount are
+	// always 0 for now: GetPage, newPage, and FlushPage/FlushAllPages, the
+	// methods that would maintain them, are still unimplemented stubs
+	// elsewhere in this file.
+	HitCount      int64 // The number of GetPage calls served without going to disk.
+	MissCount     int64 // The number of GetPage calls that had to read from disk.
+	EvictionCount int64 // The number of pages evicted from the buffer pool to make room for another.
+	DirtyFlushes  int64 // The number of dirty pages written back to disk.
+}
+
+// Stats returns a snapshot of this pager's buffer pool activity.
+func (pager *Pager) Stats() PagerStats {
+	pager.ptMtx.Lock()
+	defer pager.ptMtx.Unlock()
+	return PagerStats{
+		Pagesize:      Pagesize,
+		NumPages:      pager.numPages,
+		FreeListLen:   int64(len(pager.freePNs)),
+		PinnedCount:   pager.pinnedCount,
+		UnpinnedCount: pager.unpinnedCount,
+		HitCount:      pager.hitCount,
+		MissCount:     pager.missCount,
+		EvictionCount: pager.evictionCount,
+		DirtyFlushes:  pager.dirtyFlushes,
+	}
 }
 
 // New constructs a new Pager, backing it with a database file at the specified filePath.
@@ -69,12 +133,117 @@ func (pager *Pager) GetNumPages() (numPages int64) {
 	return pager.numPages
 }
 
-// GetFreePN returns the next available page number.
+// GetFreePN returns the next available page number, reusing a page number
+// released via FreePage when one is available before extending the file.
 func (pager *Pager) GetFreePN() (nextPN int64) {
+	pager.ptMtx.Lock()
+	defer pager.ptMtx.Unlock()
+	return pager.getFreePNLocked()
+}
+
+// getFreePNLocked is the lock-free core of GetFreePN. The ptMtx must already
+// be held by the caller. Code that already holds ptMtx (such as a caller of
+// newPage picking its pagenum) must call this instead of GetFreePN - GetFreePN locking
+// again from inside that same call would deadlock, since sync.Mutex isn't
+// reentrant.
+func (pager *Pager) getFreePNLocked() (nextPN int64) {
+	if n := len(pager.freePNs); n > 0 {
+		nextPN = pager.freePNs[n-1]
+		pager.freePNs = pager.freePNs[:n-1]
+		return nextPN
+	}
 	// Assign the first page number beyond the end of the file.
 	return pager.numPages
 }
 
+// FreePage marks pagenum as no longer in use, making it eligible for reuse
+// by a future call to GetFreePN instead of growing the backing file. The
+// caller is responsible for making sure the page isn't still pinned/in the
+// page table before freeing it.
+func (pager *Pager) FreePage(pagenum int64) error {
+	pager.ptMtx.Lock()
+	defer pager.ptMtx.Unlock()
+	if pagenum < 0 || pagenum >= pager.numPages {
+		return errors.New("cannot free a pagenum outside the current file")
+	}
+	for _, freePN := range pager.freePNs {
+		if freePN == pagenum {
+			return errors.New("pagenum is already free")
+		}
+	}
+	pager.freePNs = append(pager.freePNs, pagenum)
+	return nil
+}
+
+// freelistPath returns the path of the sidecar file this pager persists its
+// on-disk free-pagenum list to.
+func (pager *Pager) freelistPath() string {
+	return pager.file.Name() + freelistSuffix
+}
+
+// loadFreelist populates pager.freePNs from this pager's freelist sidecar
+// file, if one exists. A missing sidecar file (e.g. a brand-new database) is
+// not an error - it just means there are no free pages yet.
+func (pager *Pager) loadFreelist() error {
+	data, err := os.ReadFile(pager.freelistPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if len(data)%8 != 0 {
+		return errors.New("freelist file has been corrupted")
+	}
+	pager.freePNs = make([]int64, 0, len(data)/8)
+	for offset := 0; offset < len(data); offset += 8 {
+		pager.freePNs = append(pager.freePNs, int64(binary.LittleEndian.Uint64(data[offset:offset+8])))
+	}
+	return nil
+}
+
+// persistFreelist writes the current in-memory free-pagenum list out to this
+// pager's freelist sidecar file so it survives across Open/Close.
+// The ptMtx should be locked on entry.
+func (pager *Pager) persistFreelist() error {
+	data := make([]byte, len(pager.freePNs)*8)
+	for i, pn := range pager.freePNs {
+		binary.LittleEndian.PutUint64(data[i*8:(i+1)*8], uint64(pn))
+	}
+	return os.WriteFile(pager.freelistPath(), data, 0666)
+}
+
+// Compact truncates the backing file when every page at the tail of the file
+// is free, reclaiming disk space that FreePage alone can't recover. Pages
+// that remain free but aren't at the tail stay in the freelist for reuse by
+// GetFreePN.
+func (pager *Pager) Compact() error {
+	pager.ptMtx.Lock()
+	defer pager.ptMtx.Unlock()
+	free := make(map[int64]bool, len(pager.freePNs))
+	for _, pn := range pager.freePNs {
+		free[pn] = true
+	}
+	newNumPages := pager.numPages
+	for newNumPages > 0 && free[newNumPages-1] {
+		newNumPages--
+	}
+	if newNumPages == pager.numPages {
+		return nil
+	}
+	if err := pager.file.Truncate(newNumPages * Pagesize); err != nil {
+		return err
+	}
+	pager.numPages = newNumPages
+	remaining := pager.freePNs[:0]
+	for _, pn := range pager.freePNs {
+		if pn < newNumPages {
+			remaining = append(remaining, pn)
+		}
+	}
+	pager.freePNs = remaining
+	return nil
+}
+
 // Open (re-)initializes our pager with a database file at the specified filePath.
 //
 // If the database file didn't exist previously, it is created.
@@ -105,7 +274,7 @@ func (pager *Pager) Open(filePath string) (err error) {
 	}
 	// Set the number of pages and hand off initialization to someone else.
 	pager.numPages = len / Pagesize
-	return nil
+	return pager.loadFreelist()
 }
 
 // Close signals our pager to flush all dirty pages to disk
@@ -121,6 +290,9 @@ func (pager *Pager) Close() error {
 	}
 	// Cleanup.
 	pager.FlushAllPages()
+	if err := pager.persistFreelist(); err != nil {
+		return err
+	}
 	return pager.file.Close()
 }
 
@@ -138,17 +310,30 @@ func (pager *Pager) FillPageFromDisk(page *Page) error {
 
 // newPage returns a currently unused Page from the free or unpinned list,
 // or an ErrRanOutOfPages if there are no unused pages available.
-// The ptMtx should be locked on entry.
+// The ptMtx should be locked on entry. Callers that need a pagenum for this
+// page (e.g. the eventual GetNewPage) must get it via getFreePNLocked, not
+// GetFreePN - GetFreePN takes ptMtx itself and would deadlock here.
+// TODO(stats): once implemented, increment pager.evictionCount whenever this
+// pulls a page off unpinnedList instead of freeList, so Stats().EvictionCount
+// reflects real buffer pool pressure.
 func (pager *Pager) newPage(pagenum int64) (newPage *Page, err error) {
 	panic("Not implemented yet")
 }
 
 // GetNewPage returns a new Page with the next available pagenum
+// TODO(stats): once implemented, increment pager.pinnedCount for the page
+// this returns - it's handing back a pinned page, same as GetPage - or
+// PutPage's matching decrement will drive pinnedCount negative.
 func (pager *Pager) GetNewPage() (page *Page, err error) {
 	panic("Not implemented yet")
 }
 
 // GetPage returns an existing Page corresponding to the given pagenum.
+// TODO(stats): once implemented, increment pager.hitCount when pagenum is
+// already in pageTable and pager.missCount when it has to be loaded from
+// disk via newPage/FillPageFromDisk, so Stats().HitCount/MissCount are real.
+// Also increment pager.pinnedCount for the page this returns, symmetric with
+// PutPage's decrement - see the TODO(stats) on GetNewPage for why that matters.
 func (pager *Pager) GetPage(pagenum int64) (page *Page, err error) {
 	panic("Not implemented yet")
 }
@@ -165,6 +350,8 @@ func (pager *Pager) PutPage(page *Page) (err error) {
 		link.PopSelf()
 		newLink := pager.unpinnedList.PushTail(page)
 		pager.pageTable[page.pagenum] = newLink
+		pager.pinnedCount--
+		pager.unpinnedCount++
 	}
 	if ret < 0 {
 		return errors.New("pinCount for page is < 0")
@@ -173,6 +360,8 @@ func (pager *Pager) PutPage(page *Page) (err error) {
 }
 
 // FlushPage flushes a particular page's data to disk if it is dirty.
+// TODO(stats): once implemented, increment pager.dirtyFlushes each time this
+// actually writes page.data to disk, so Stats().DirtyFlushes is real.
 func (pager *Pager) FlushPage(page *Page) {
 	panic("Not implemented yet")
 }
@@ -181,3 +370,74 @@ func (pager *Pager) FlushPage(page *Page) {
 func (pager *Pager) FlushAllPages() {
 	panic("Not implemented yet")
 }
+
+// WriteTo streams a consistent copy of this pager's backing file to w, page
+// by page, using the same directio-aligned buffer shape the buffer pool
+// itself uses so taking a backup doesn't thrash the page cache. Modeled
+// after bbolt's Tx.WriteTo.
+//
+// Tx fixes the page *count* at Begin but copies a page's contents only when
+// it first reads that page, so a per-page read through it wouldn't stop a concurrent writer's in-progress
+// multi-page write (e.g. a B-tree split) from leaving the backup with some
+// pages copied pre-update and others post-update. WriteTo instead holds
+// ptMtx for its entire duration - every other page mutation in this file
+// also goes through ptMtx - so no writer can run while the backup streams.
+// That serializes backups against the rest of the pager, pausing buffer pool
+// activity for as long as w.Write takes; see the backup REPL command's usage
+// string for that trade-off spelled out to users.
+//
+// WriteTo does not call FlushAllPages first: that's still an unimplemented
+// stub elsewhere in this file, and calling it would panic on every backup.
+// Until FlushPage/FlushAllPages exist, WriteTo can only copy what's already
+// on disk - a page dirtied in the buffer pool but not yet flushed won't show
+// up in the backup. Add the FlushAllPages call back in here once it's real.
+func (pager *Pager) WriteTo(w io.Writer) (n int64, err error) {
+	pager.ptMtx.Lock()
+	defer pager.ptMtx.Unlock()
+
+	numPages := pager.numPages
+
+	buf := directio.AlignedBlock(int(Pagesize))
+	for pn := int64(0); pn < numPages; pn++ {
+		if _, err := pager.file.ReadAt(buf, pn*Pagesize); err != nil && err != io.EOF {
+			return n, err
+		}
+		written, werr := w.Write(buf)
+		n += int64(written)
+		if werr != nil {
+			return n, werr
+		}
+	}
+	return n, nil
+}
+
+// Restore reads a backup produced by [*Pager.WriteTo] from r and writes it
+// out as a new database file at filePath, validating that every page read
+// off r is a full Pagesize-aligned block before committing it to disk.
+func Restore(filePath string, r io.Reader) (err error) {
+	file, err := directio.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buf := directio.AlignedBlock(int(Pagesize))
+	var offset int64
+	for {
+		read, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			break
+		}
+		if err == io.ErrUnexpectedEOF || int64(read) != Pagesize {
+			return errors.New("backup is not aligned to pagesize")
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := file.WriteAt(buf, offset); err != nil {
+			return err
+		}
+		offset += Pagesize
+	}
+	return file.Sync()
+}
@@ -0,0 +1,36 @@
+package pager
+
+import (
+	"errors"
+	"fmt"
+
+	"dinodb/pkg/repl"
+)
+
+// statsUsage is the REPL usage string shown for the stats command.
+const statsUsage = "stats - print buffer pool statistics for the current pager"
+
+// RegisterStatsCommand adds the `stats` command to pRepl, next to the
+// rest of the pager project's REPL commands. There is no matching `hash
+// stats` command: pkg/hash isn't part of this tree, so hash.HashTable has no
+// Stats method to call here.
+func RegisterStatsCommand(pRepl *repl.REPL) error {
+	return pRepl.AddCommand("stats", handleStats, statsUsage)
+}
+
+// handleStats implements the pager REPL's `stats` command: it prints a
+// snapshot of the current pager's buffer pool activity, useful for
+// diagnosing the thrash that can make Grace Hash Join slow on unbalanced
+// inputs.
+func handleStats(payload string, replConfig *repl.REPLConfig) error {
+	pager, found := replConfig.GetAttribute("pager").(*Pager)
+	if !found || pager == nil {
+		return errors.New("no pager loaded - open a database before running stats")
+	}
+	stats := pager.Stats()
+	fmt.Fprintf(replConfig.GetWriter(),
+		"pagesize=%d numPages=%d freeListLen=%d pinned=%d unpinned=%d hits=%d misses=%d evictions=%d dirtyFlushes=%d\n",
+		stats.Pagesize, stats.NumPages, stats.FreeListLen, stats.PinnedCount, stats.UnpinnedCount,
+		stats.HitCount, stats.MissCount, stats.EvictionCount, stats.DirtyFlushes)
+	return nil
+}
@@ -0,0 +1,170 @@
+package pager
+
+import "errors"
+
+// ErrTxDone is returned when a Tx is used after it has already been
+// committed or rolled back.
+var ErrTxDone = errors.New("transaction has already been committed or rolled back")
+
+// Tx is a snapshot transaction over a Pager, modeled after bbolt's Tx. It
+// captures the pager's page count at Begin time, so pages allocated afterward
+// are not visible through it. A read-only Tx copies a page's contents the
+// first time it reads that page; writes made to the page between Begin and
+// that first read are seen, later writes are not.
+//
+// Nothing in this tree calls Begin except tx_test.go: pkg/join's Grace Hash
+// Join still builds its hash tables and probes buckets straight through the
+// raw Pager, so Tx does not give Join the snapshot isolation it would need
+// against a concurrent writer. Wiring that up means threading a *Tx through
+// buildHashIndex and probeBuckets, which in turn means those can no longer be
+// unconditional panics - so it's blocked on the same pkg/hash gap that blocks
+// probeBuckets today, not on anything here.
+type Tx struct {
+	pager    *Pager
+	writable bool
+	numPages int64 // The pager's page count as of Begin; read-only Tx's never see pages beyond this.
+	done     bool
+	// overlay caches, per pagenum, the *Page this Tx hands back from GetPage
+	// and GetNewPage:
+	//  - for a read-only Tx, it holds a private copy of the page's data,
+	//    snapshotted the moment it's first read, so a concurrent writable Tx
+	//    dirtying that same pagenum afterward is never observed here.
+	//  - for a writable Tx, it holds the live, pinned *Page itself, so writes
+	//    land on it directly.
+	overlay map[int64]*Page
+	// allocated tracks pagenums this (writable) Tx allocated via GetNewPage.
+	// Rollback unpins and frees them so an aborted allocation doesn't
+	// permanently leak a page out of the real pager.
+	allocated []int64
+}
+
+// Begin starts a new transaction against pager. A writable transaction may
+// allocate and dirty pages; a read-only transaction sees only the pages that
+// existed as of this call, each with its contents as of the transaction's
+// first read of it (see GetPage), not as of this call.
+func (pager *Pager) Begin(writable bool) (tx *Tx, err error) {
+	pager.ptMtx.Lock()
+	defer pager.ptMtx.Unlock()
+	return &Tx{
+		pager:    pager,
+		writable: writable,
+		numPages: pager.numPages,
+		overlay:  make(map[int64]*Page),
+	}, nil
+}
+
+// Writable reports whether this Tx can allocate or dirty pages.
+func (tx *Tx) Writable() bool {
+	return tx.writable
+}
+
+// GetPage returns the page for pagenum as seen by this transaction.
+//
+// A read-only Tx refuses to return pages allocated after Begin, and the
+// first time it reads a given pagenum, it snapshots that page's bytes into a
+// private copy: later writes a concurrent writable Tx makes to the live page
+// are never observed through this Tx, even though it never itself goes
+// through Commit/Rollback to "see" them. A writable Tx instead caches the
+// live, pinned page directly, since its writes are meant to land on it.
+//
+// This calls straight through to Pager.GetPage for any pagenum not already
+// in its overlay, which is still an unimplemented stub - so today, the first
+// GetPage on any new pagenum panics. That's a pre-existing gap in Pager, not
+// something Tx papers over or works around.
+func (tx *Tx) GetPage(pagenum int64) (page *Page, err error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	if pagenum >= tx.numPages {
+		return nil, errors.New("pagenum is beyond this transaction's snapshot")
+	}
+	if cached, ok := tx.overlay[pagenum]; ok {
+		return cached, nil
+	}
+	livePage, err := tx.pager.GetPage(pagenum)
+	if err != nil {
+		return nil, err
+	}
+	if tx.writable {
+		tx.overlay[pagenum] = livePage
+		return livePage, nil
+	}
+	// Read-only: copy the bytes out, then release our pin on the live page -
+	// the snapshot we just took no longer depends on it staying in the
+	// buffer pool.
+	snapshot := &Page{
+		pager:   tx.pager,
+		pagenum: livePage.pagenum,
+		dirty:   false,
+		data:    append([]byte(nil), livePage.data...),
+	}
+	tx.overlay[pagenum] = snapshot
+	if err := tx.pager.PutPage(livePage); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// GetNewPage allocates a new page through this (necessarily writable)
+// transaction. The page is allocated in the pager itself, so transactions
+// begun afterward see it without waiting for Commit. If this Tx is
+// Rollback'd, the page is unpinned and freed so it doesn't leak out of the
+// pager.
+//
+// Like GetPage, this calls straight through to Pager.GetNewPage, itself still
+// an unimplemented stub - so today, calling this panics rather than
+// allocating anything.
+func (tx *Tx) GetNewPage() (page *Page, err error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	if !tx.writable {
+		return nil, errors.New("cannot allocate a new page in a read-only transaction")
+	}
+	page, err = tx.pager.GetNewPage()
+	if err != nil {
+		return nil, err
+	}
+	tx.overlay[page.pagenum] = page
+	tx.allocated = append(tx.allocated, page.pagenum)
+	tx.numPages = tx.pager.numPages
+	return page, nil
+}
+
+// PutPage releases a reference to a page previously returned by this Tx's GetPage/GetNewPage.
+func (tx *Tx) PutPage(page *Page) error {
+	return tx.pager.PutPage(page)
+}
+
+// Commit finalizes a writable transaction by marking it done. Its writes and
+// allocations already went to the live pager as they were made, so Commit
+// does not publish anything further.
+func (tx *Tx) Commit() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	if !tx.writable {
+		return errors.New("cannot commit a read-only transaction")
+	}
+	tx.done = true
+	return nil
+}
+
+// Rollback ends this transaction. Any pages allocated via GetNewPage are
+// unpinned and freed back to the pager instead of being left permanently
+// allocated. Writes already made through this Tx to the live pages are not
+// undone. Read-only transactions must
+// call Rollback (instead of Commit) once done with their snapshot.
+func (tx *Tx) Rollback() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+	for _, pagenum := range tx.allocated {
+		if page, ok := tx.overlay[pagenum]; ok {
+			tx.pager.PutPage(page)
+		}
+		tx.pager.FreePage(pagenum)
+	}
+	return nil
+}
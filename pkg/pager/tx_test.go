@@ -0,0 +1,110 @@
+package pager
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestTxSnapshotExcludesLaterPages checks the core isolation guarantee
+// Begin provides: a Tx pins the pager's page count at Begin time, so pages
+// allocated by a concurrent writer afterward aren't visible through it, even
+// though the underlying GetPage/GetNewPage buffer pool methods this Tx
+// ultimately calls into aren't implemented yet in this tree.
+func TestTxSnapshotExcludesLaterPages(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tx_snapshot.db")
+	pager, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	defer closeWithoutFlush(t, pager)
+
+	pager.numPages = 3
+	tx, err := pager.Begin(false)
+	if err != nil {
+		t.Fatalf("Begin() returned error: %v", err)
+	}
+
+	// A concurrent writer extends the file after the snapshot was taken.
+	pager.numPages = 5
+
+	if _, err := tx.GetPage(4); err == nil {
+		t.Fatalf("expected GetPage to refuse a pagenum allocated after Begin")
+	}
+}
+
+// TestTxLifecycle exercises the read-only/writable restrictions and the
+// done-state bookkeeping around Commit/Rollback.
+func TestTxLifecycle(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tx_lifecycle.db")
+	pager, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	defer closeWithoutFlush(t, pager)
+
+	readTx, err := pager.Begin(false)
+	if err != nil {
+		t.Fatalf("Begin(false) returned error: %v", err)
+	}
+	if readTx.Writable() {
+		t.Fatalf("expected a Begin(false) Tx to be read-only")
+	}
+	if err := readTx.Commit(); err == nil {
+		t.Fatalf("expected Commit on a read-only Tx to error")
+	}
+	if _, err := readTx.GetNewPage(); err == nil {
+		t.Fatalf("expected GetNewPage on a read-only Tx to error")
+	}
+	if err := readTx.Rollback(); err != nil {
+		t.Fatalf("Rollback() returned error: %v", err)
+	}
+	if err := readTx.Rollback(); err != ErrTxDone {
+		t.Fatalf("expected a second Rollback to return ErrTxDone, got %v", err)
+	}
+	if _, err := readTx.GetPage(0); err != ErrTxDone {
+		t.Fatalf("expected GetPage after Rollback to return ErrTxDone, got %v", err)
+	}
+
+	writeTx, err := pager.Begin(true)
+	if err != nil {
+		t.Fatalf("Begin(true) returned error: %v", err)
+	}
+	if !writeTx.Writable() {
+		t.Fatalf("expected a Begin(true) Tx to be writable")
+	}
+	if err := writeTx.Commit(); err != nil {
+		t.Fatalf("Commit() returned error: %v", err)
+	}
+	if err := writeTx.Commit(); err != ErrTxDone {
+		t.Fatalf("expected a second Commit to return ErrTxDone, got %v", err)
+	}
+}
+
+// TestTxRollbackFreesAllocatedPages simulates what GetNewPage would hand
+// back (the real buffer pool allocator is still an unimplemented stub in
+// this tree) and checks that Rollback returns any pages recorded in
+// tx.allocated to the pager's freelist instead of leaking them.
+func TestTxRollbackFreesAllocatedPages(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tx_rollback.db")
+	pager, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	defer closeWithoutFlush(t, pager)
+
+	pn := pager.GetFreePN()
+	pager.numPages = pn + 1
+
+	tx, err := pager.Begin(true)
+	if err != nil {
+		t.Fatalf("Begin(true) returned error: %v", err)
+	}
+	tx.allocated = append(tx.allocated, pn)
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() returned error: %v", err)
+	}
+	if len(pager.freePNs) != 1 || pager.freePNs[0] != pn {
+		t.Fatalf("expected Rollback to free pagenum %d, freePNs=%v", pn, pager.freePNs)
+	}
+}